@@ -0,0 +1,10 @@
+package constants
+
+const (
+	// Name is the binary name, shown in --help and error messages.
+	Name = "render"
+	// Description is a one-line summary of what render does, shown next to Name in --help.
+	Description = "renders Go templates from the command line"
+	// Version is the current version of render, overridden at build time via -ldflags.
+	Version = "dev"
+)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunWithoutOutIsAnError(t *testing.T) {
+	stdin := "test-{{ .something }}-test"
+	stdout, stderr, err := runStdin(&stdin, "--var=something=test", "--dry-run")
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Equal(t, "", stdout)
+	assert.Contains(t, stderr, "--dry-run has nothing to compare against without --out or --outdir")
+}
+
+func TestDryRunWithOutReportsNoChange(t *testing.T) {
+	in, err := ioutil.TempFile("", "render-dry-run-in-")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(in.Name()) }()
+	assert.NoError(t, ioutil.WriteFile(in.Name(), []byte("test-{{ .something }}-test"), 0644))
+
+	dest, err := ioutil.TempFile("", "render-dry-run-out-")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(dest.Name()) }()
+	assert.NoError(t, ioutil.WriteFile(dest.Name(), []byte("test-test-test"), 0644))
+
+	_, _, err = run("--var=something=test", "--in", in.Name(), "--dry-run", "--out", dest.Name())
+	assert.NoError(t, err)
+}
+
+func TestDryRunWithOutReportsChange(t *testing.T) {
+	in, err := ioutil.TempFile("", "render-dry-run-in-")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(in.Name()) }()
+	assert.NoError(t, ioutil.WriteFile(in.Name(), []byte("test-{{ .something }}-test"), 0644))
+
+	dest, err := ioutil.TempFile("", "render-dry-run-out-")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(dest.Name()) }()
+	assert.NoError(t, ioutil.WriteFile(dest.Name(), []byte("stale"), 0644))
+
+	_, _, err = run("--var=something=test", "--in", in.Name(), "--dry-run", "--out", dest.Name())
+	assert.EqualError(t, err, "exit status 1")
+}
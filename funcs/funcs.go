@@ -0,0 +1,121 @@
+// Package funcs manages the catalog of template functions render exposes,
+// and lets callers build a restricted subset of it via an allowlist,
+// denylist or the --safe profile.
+package funcs
+
+import (
+	"reflect"
+	"sort"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
+)
+
+// Entry describes a single template function, enough for a user to audit
+// what a template could do without reading the binary's source.
+type Entry struct {
+	Name     string
+	Package  string
+	Arity    int
+	Variadic bool
+	Safe     bool
+}
+
+// unsafe lists sprig functions that leak host state (environment variables,
+// DNS resolution) into rendered output, and so are excluded by --safe.
+var unsafe = map[string]bool{
+	"env":           true,
+	"expandenv":     true,
+	"getHostByName": true,
+}
+
+// Catalog returns metadata for every function render can expose, sorted by
+// name, regardless of whether it is currently allowed.
+func Catalog() []Entry {
+	fm := sprig.TxtFuncMap()
+	entries := make([]Entry, 0, len(fm)+1)
+	for name, fn := range fm {
+		entries = append(entries, describe(name, "sprig", fn))
+	}
+	entries = append(entries, Entry{Name: "render", Package: "render", Arity: 2, Safe: true})
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func describe(name, pkg string, fn interface{}) Entry {
+	t := reflect.TypeOf(fn)
+	return Entry{
+		Name:     name,
+		Package:  pkg,
+		Arity:    t.NumIn(),
+		Variadic: t.IsVariadic(),
+		Safe:     !unsafe[name],
+	}
+}
+
+// renderPlaceholder stands in for the "render" function in Build's
+// candidate set, so --funcs-allow/--funcs-deny can name it like any other
+// function. It is never actually called: render.go's funcMap rebinds
+// "render" to the real, context-bound closure whenever Build's output
+// still contains this placeholder, and leaves it absent otherwise.
+var renderPlaceholder = func(map[string]interface{}, string) (string, error) {
+	return "", errors.New("render: called before being bound to a rendering context")
+}
+
+// Build returns the sprig text/template.FuncMap restricted to the given
+// allow/deny lists and, when safe is true, with every unsafe function
+// removed. An empty allow list means "everything is allowed" before deny is
+// applied. Unknown names in allow or deny are reported as errors, so a typo
+// fails fast instead of silently matching nothing.
+func Build(allow, deny []string, safe bool) (template.FuncMap, error) {
+	full := sprig.TxtFuncMap()
+	full["render"] = renderPlaceholder
+
+	known := make(map[string]bool, len(full))
+	for name := range full {
+		known[name] = true
+	}
+	if err := checkKnown(known, allow); err != nil {
+		return nil, err
+	}
+	if err := checkKnown(known, deny); err != nil {
+		return nil, err
+	}
+
+	allowSet := toSet(allow)
+	denySet := toSet(deny)
+
+	fm := template.FuncMap{}
+	for name, fn := range full {
+		if safe && unsafe[name] {
+			continue
+		}
+		if len(allowSet) > 0 && !allowSet[name] {
+			continue
+		}
+		if denySet[name] {
+			continue
+		}
+		fm[name] = fn
+	}
+	return fm, nil
+}
+
+func checkKnown(known map[string]bool, names []string) error {
+	for _, name := range names {
+		if !known[name] {
+			return errors.Errorf("unknown template function '%s'", name)
+		}
+	}
+	return nil
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,80 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_DefaultIncludesEverythingExceptNothing(t *testing.T) {
+	fm, err := Build(nil, nil, false)
+	require.NoError(t, err)
+	assert.Contains(t, fm, "upper")
+	assert.Contains(t, fm, "env")
+	assert.Contains(t, fm, "render")
+}
+
+func TestBuild_Safe(t *testing.T) {
+	fm, err := Build(nil, nil, true)
+	require.NoError(t, err)
+	assert.Contains(t, fm, "upper")
+	assert.NotContains(t, fm, "env")
+	assert.NotContains(t, fm, "expandenv")
+	assert.NotContains(t, fm, "getHostByName")
+}
+
+func TestBuild_Allow(t *testing.T) {
+	fm, err := Build([]string{"upper", "lower"}, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, fm, 2)
+	assert.Contains(t, fm, "upper")
+	assert.Contains(t, fm, "lower")
+}
+
+func TestBuild_DenyRender(t *testing.T) {
+	fm, err := Build(nil, []string{"render"}, false)
+	require.NoError(t, err)
+	assert.NotContains(t, fm, "render")
+	assert.Contains(t, fm, "upper")
+}
+
+func TestBuild_AllowRenderOnly(t *testing.T) {
+	fm, err := Build([]string{"render"}, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, fm, 1)
+	assert.Contains(t, fm, "render")
+}
+
+func TestBuild_UnknownAllowIsAnError(t *testing.T) {
+	_, err := Build([]string{"not-a-real-function"}, nil, false)
+	assert.Error(t, err)
+}
+
+func TestBuild_UnknownDenyIsAnError(t *testing.T) {
+	_, err := Build(nil, []string{"not-a-real-function"}, false)
+	assert.Error(t, err)
+}
+
+func TestCatalog_IncludesRenderAsSafe(t *testing.T) {
+	entries := Catalog()
+
+	var found *Entry
+	for i := range entries {
+		if entries[i].Name == "render" {
+			found = &entries[i]
+			break
+		}
+	}
+
+	require.NotNil(t, found)
+	assert.Equal(t, "render", found.Package)
+	assert.True(t, found.Safe)
+}
+
+func TestCatalog_IsSortedByName(t *testing.T) {
+	entries := Catalog()
+	for i := 1; i < len(entries); i++ {
+		assert.LessOrEqual(t, entries[i-1].Name, entries[i].Name)
+	}
+}
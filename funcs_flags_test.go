@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncsDenyRemovesFunction(t *testing.T) {
+	stdin := "{{ upper .name }}"
+	stdout, stderr, err := runStdin(&stdin, "--var=name=render", "--funcs-deny=upper")
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Equal(t, "", stdout)
+	assert.Contains(t, stderr, "function \"upper\" not defined")
+}
+
+func TestFuncsAllowRestrictsToListedFunctions(t *testing.T) {
+	stdin := "{{ upper .name }}"
+	stdout, _, err := runStdin(&stdin, "--var=name=render", "--funcs-allow=upper")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "RENDER", stdout)
+}
+
+func TestSafeHidesEnvFunction(t *testing.T) {
+	stdin := `{{ env "HOME" }}`
+	_, stderr, err := runStdin(&stdin, "--safe")
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Contains(t, stderr, "function \"env\" not defined")
+}
+
+func TestFuncsDenyUnknownFunctionIsAnError(t *testing.T) {
+	stdin := "test"
+	_, stderr, err := runStdin(&stdin, "--funcs-deny=not-a-real-function")
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Contains(t, stderr, "unknown template function 'not-a-real-function'")
+}
+
+func TestFuncsCommandSafeExcludesUnsafeFunctions(t *testing.T) {
+	stdout, _, err := run("funcs", "--safe")
+
+	assert.NoError(t, err)
+	assert.NotContains(t, stdout, "env ")
+	assert.Contains(t, stdout, "upper ")
+}
+
+func TestFuncsCommandAllowRestrictsToListedFunctions(t *testing.T) {
+	stdout, _, err := run("funcs", "--funcs-allow=upper")
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, "upper ")
+	assert.NotContains(t, stdout, "lower ")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/VirtusLab/render/funcs"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// funcsCommand implements `render funcs`, which prints the catalog of
+// template functions render can expose (name, arity, package, safe/unsafe).
+// Given --funcs-allow/--funcs-deny/--safe, it prints the active catalog for
+// that restriction instead of the full, unfiltered set, so users can verify
+// exactly what a given rendering environment would expose without forking
+// the binary.
+var funcsCommand = cli.Command{
+	Name:  "funcs",
+	Usage: "print the catalog of available template functions",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "funcs-allow",
+			Usage: "only include this template function, can be used multiple times",
+		},
+		cli.StringSliceFlag{
+			Name:  "funcs-deny",
+			Usage: "exclude this template function, can be used multiple times",
+		},
+		cli.BoolFlag{
+			Name:  "safe",
+			Usage: "exclude env/OS/network template functions",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		active, err := funcs.Build(c.StringSlice("funcs-allow"), c.StringSlice("funcs-deny"), c.Bool("safe"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "%-20s %-10s %-6s %s\n", "NAME", "PACKAGE", "ARITY", "SAFE")
+		for _, e := range funcs.Catalog() {
+			if _, ok := active[e.Name]; !ok {
+				continue
+			}
+			fmt.Fprintf(os.Stdout, "%-20s %-10s %-6d %t\n", e.Name, e.Package, e.Arity, e.Safe)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"os"
+
+	"github.com/VirtusLab/go-extended/pkg/files"
+	"github.com/VirtusLab/render/constants"
+	"github.com/VirtusLab/render/funcs"
+	"github.com/VirtusLab/render/schema"
+	"github.com/VirtusLab/render/source"
+	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// renderWithContext renders template content against vars content merged on
+// top of an empty context, for use by commands (such as `render test`) that
+// need to drive the exact same rendering path as the default action.
+func renderWithContext(templateContent, varsContent []byte, unsafeIgnoreMissingKeys bool) (stdout, stderr string, err error) {
+	data := map[string]interface{}{}
+	if len(varsContent) > 0 {
+		var vars map[string]interface{}
+		if err := yaml.Unmarshal(varsContent, &vars); err != nil {
+			return "", "", errors.Wrap(err, "cannot parse vars.yaml")
+		}
+		if err := mergo.Merge(&data, vars, mergo.WithOverride); err != nil {
+			return "", "", errors.Wrap(err, "cannot merge vars.yaml")
+		}
+	}
+
+	missingKey := "missingkey=error"
+	if unsafeIgnoreMissingKeys {
+		missingKey = "missingkey=invalid"
+	}
+
+	base, err := funcs.Build(nil, nil, false)
+	if err != nil {
+		return "", "", err
+	}
+
+	out, err := renderTemplate("input.tmpl", string(templateContent), data, missingKey, base)
+	if err != nil {
+		return "", err.Error(), err
+	}
+	return out, "", nil
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = constants.Name
+	app.Usage = constants.Description
+	app.Version = constants.Version
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "d",
+			Usage: "enable debug logging",
+		},
+		cli.StringSliceFlag{
+			Name:  "config",
+			Usage: "config file to merge into the template context, can be used multiple times",
+		},
+		cli.StringSliceFlag{
+			Name:  "var",
+			Usage: "a key=value pair (dot notation supported) to merge into the template context, can be used multiple times",
+		},
+		cli.StringFlag{
+			Name:  "in",
+			Usage: "input template file, defaults to stdin",
+		},
+		cli.StringFlag{
+			Name:  "indir",
+			Usage: "input directory, rendered recursively, mutually exclusive with --in",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "output file, mirrors --in, defaults to stdout",
+		},
+		cli.StringFlag{
+			Name:  "outdir",
+			Usage: "output directory, mirrors --indir, defaults to rendering each file in place with its .tmpl suffix stripped",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print a diff of what --out/--outdir would change instead of writing, exit 1 if anything would change",
+		},
+		cli.BoolFlag{
+			Name:  "write-if-changed",
+			Usage: "skip rewriting a destination file whose rendered content is unchanged, preserving its mtime",
+		},
+		cli.BoolFlag{
+			Name:  "unsafe-ignore-missing-keys",
+			Usage: "do not fail when a template references a key missing from the context",
+		},
+		cli.StringSliceFlag{
+			Name:  "funcs-allow",
+			Usage: "only expose this template function, can be used multiple times",
+		},
+		cli.StringSliceFlag{
+			Name:  "funcs-deny",
+			Usage: "hide this template function, can be used multiple times",
+		},
+		cli.BoolFlag{
+			Name:  "safe",
+			Usage: "hide env/OS/network template functions by default",
+		},
+		cli.BoolFlag{
+			Name:  "watch",
+			Usage: "re-render whenever --in/--indir or a --config file changes",
+		},
+		cli.DurationFlag{
+			Name:  "debounce",
+			Value: defaultDebounce,
+			Usage: "minimum time to wait after a change before re-rendering",
+		},
+		cli.StringFlag{
+			Name:  "on-change",
+			Usage: "shell command to run after a successful re-render in --watch mode",
+		},
+		cli.StringFlag{
+			Name:  "config-sha256",
+			Usage: "expected sha256 checksum of a https:// --config, for supply-chain pinning",
+		},
+		cli.BoolFlag{
+			Name:  "refresh",
+			Usage: "bypass the cache and re-fetch remote --config/--in sources",
+		},
+		cli.BoolFlag{
+			Name:  "offline",
+			Usage: "forbid network access, failing on any uncached remote --config/--in source",
+		},
+		cli.StringFlag{
+			Name:  "schema",
+			Usage: "JSON Schema file to validate the merged config+vars context against before rendering",
+		},
+	}
+	app.Action = action
+	app.Commands = []cli.Command{
+		testCommand,
+		funcsCommand,
+		schemaCommand,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		logrus.Error(err)
+		os.Exit(1)
+	}
+}
+
+func action(c *cli.Context) error {
+	if c.Bool("d") {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	if c.Bool("dry-run") && c.String("indir") == "" && c.String("out") == "" && c.String("outdir") == "" {
+		return errors.New("--dry-run has nothing to compare against without --out or --outdir")
+	}
+
+	if c.Bool("watch") {
+		return watch(c)
+	}
+
+	changed, err := renderOnce(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("dry-run") && changed {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+// renderOnce performs a single render pass using the flags on c, and
+// reports whether the destination content would change (only meaningful
+// with --dry-run).
+func renderOnce(c *cli.Context) (bool, error) {
+	data, err := buildContext(c)
+	if err != nil {
+		return false, err
+	}
+
+	if schemaPath := c.String("schema"); schemaPath != "" {
+		if err := schema.Validate(data, schemaPath); err != nil {
+			return false, err
+		}
+	}
+
+	missingKey := "missingkey=error"
+	if c.Bool("unsafe-ignore-missing-keys") {
+		missingKey = "missingkey=invalid"
+	}
+
+	base, err := funcs.Build(c.StringSlice("funcs-allow"), c.StringSlice("funcs-deny"), c.Bool("safe"))
+	if err != nil {
+		return false, err
+	}
+
+	opts := outputOptions{
+		DryRun:         c.Bool("dry-run"),
+		WriteIfChanged: c.Bool("write-if-changed"),
+	}
+
+	switch {
+	case c.String("indir") != "":
+		return renderDir(c.String("indir"), c.String("outdir"), data, missingKey, base, opts)
+	case c.String("in") != "":
+		in, err := source.Resolve(c.String("in"), sourceOptions(c))
+		if err != nil {
+			return false, err
+		}
+		return renderFile(in, data, missingKey, base, c.String("out"), opts)
+	default:
+		return false, renderStdin(data, missingKey, base)
+	}
+}
+
+// sourceOptions builds the source.Options shared by every --config/--in
+// reference resolved during this invocation.
+func sourceOptions(c *cli.Context) source.Options {
+	return source.Options{
+		Refresh: c.Bool("refresh"),
+		Offline: c.Bool("offline"),
+		SHA256:  c.String("config-sha256"),
+	}
+}
+
+// buildContext merges every --config file, in order, followed by every
+// --var override, in order, into a single template context. A --config may
+// be a plain path or a remote source.Resolve understands.
+func buildContext(c *cli.Context) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+
+	for _, ref := range c.StringSlice("config") {
+		path, err := source.Resolve(ref, sourceOptions(c))
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := files.ReadInput(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read config file '%s'", path)
+		}
+
+		var cfg map[string]interface{}
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return nil, errors.Wrapf(err, "cannot parse config file '%s'", path)
+		}
+
+		if err := mergo.Merge(&data, cfg, mergo.WithOverride); err != nil {
+			return nil, errors.Wrapf(err, "cannot merge config file '%s'", path)
+		}
+	}
+
+	for _, raw := range c.StringSlice("var") {
+		path, value, err := parseVar(raw)
+		if err != nil {
+			return nil, err
+		}
+		setNested(data, path, value)
+	}
+
+	return data, nil
+}
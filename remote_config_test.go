@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFlagAcceptsRemoteHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("name: render\n"))
+	}))
+	defer server.Close()
+
+	stdin := "hello {{ .name }}"
+	stdout, _, err := runStdin(&stdin, "--config", server.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello render", stdout)
+}
+
+func TestConfigFlagRejectsChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("name: render\n"))
+	}))
+	defer server.Close()
+
+	stdin := "hello {{ .name }}"
+	_, stderr, err := runStdin(&stdin, "--config", server.URL, "--config-sha256", "deadbeef")
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Contains(t, stderr, "checksum mismatch")
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
+)
+
+// outputOptions controls how rendered content is delivered to disk when
+// --out/--outdir are used instead of the default stdout stream.
+type outputOptions struct {
+	DryRun         bool
+	WriteIfChanged bool
+}
+
+// funcMap builds the template function map for the given context on top of
+// base (the active, already allow/deny-filtered function catalog). When
+// base still contains "render" (funcs.Build leaves its placeholder in
+// unless --funcs-deny=render or an allowlist excludes it), it is rebound
+// here to the real, context-bound closure that re-renders a string as a
+// nested template against data overridden with the supplied map. This is
+// what powers constructs like `{{ .inner | render .override }}`.
+func funcMap(data map[string]interface{}, base template.FuncMap) template.FuncMap {
+	fm := template.FuncMap{}
+	for name, fn := range base {
+		fm[name] = fn
+	}
+	if _, ok := base["render"]; ok {
+		fm["render"] = func(override map[string]interface{}, input string) (string, error) {
+			merged := map[string]interface{}{}
+			if err := mergo.Merge(&merged, data); err != nil {
+				return "", errors.Wrap(err, "cannot merge render context")
+			}
+			if err := mergo.Merge(&merged, override, mergo.WithOverride); err != nil {
+				return "", errors.Wrap(err, "cannot merge render override")
+			}
+			return renderTemplate("nested", input, merged, "missingkey=error", base)
+		}
+	}
+	return fm
+}
+
+// renderTemplate parses and executes input as a Go template named name,
+// using data as the context, missingKey as the text/template "missingkey"
+// option (e.g. "missingkey=error" or "missingkey=invalid"), and base as the
+// allowed function catalog.
+func renderTemplate(name, input string, data map[string]interface{}, missingKey string, base template.FuncMap) (string, error) {
+	tmpl, err := template.New(name).
+		Option(missingKey).
+		Funcs(funcMap(data, base)).
+		Parse(input)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot parse template '%s'", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "cannot render template '%s'", name)
+	}
+	return buf.String(), nil
+}
+
+// renderStdin renders the template piped in on stdin, failing fast when
+// stdin is a terminal (i.e. nothing was actually piped in).
+func renderStdin(data map[string]interface{}, missingKey string, base template.FuncMap) error {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return errors.Wrap(err, "cannot stat stdin")
+	}
+	if stat.Mode()&os.ModeCharDevice != 0 {
+		return errors.New("expected either stdin, --indir or --in parameter, for usage use --help")
+	}
+
+	input, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return errors.Wrap(err, "cannot read stdin")
+	}
+
+	out, err := renderTemplate("stdin", string(input), data, missingKey, base)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.WriteString(out)
+	return err
+}
+
+// renderFile renders a single template file. When dest is empty the result
+// is written to stdout; otherwise it is delivered to dest according to
+// opts, preserving the source file's mode. It reports whether dest would
+// change (always false when writing to stdout).
+func renderFile(path string, data map[string]interface{}, missingKey string, base template.FuncMap, dest string, opts outputOptions) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot stat input file '%s'", path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot read input file '%s'", path)
+	}
+
+	out, err := renderTemplate(filepath.Base(path), string(content), data, missingKey, base)
+	if err != nil {
+		return false, err
+	}
+
+	if dest == "" {
+		_, err = os.Stdout.WriteString(out)
+		return false, err
+	}
+
+	return writeOutput(dest, []byte(out), info.Mode(), opts)
+}
+
+// renderDir walks dir recursively, rendering every *.tmpl file it finds. If
+// outDir is empty each result is written in place, next to its source file
+// with the .tmpl suffix stripped (concatenating every result to stdout
+// instead would interleave unrelated files with no way to tell them apart);
+// otherwise each result is written under outDir at the same relative path
+// (.tmpl suffix stripped as well), mirroring the source tree. It reports
+// whether any destination file would change.
+func renderDir(dir, outDir string, data map[string]interface{}, missingKey string, base template.FuncMap, opts outputOptions) (bool, error) {
+	anyChanged := false
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+			return nil
+		}
+
+		destName := strings.TrimSuffix(path, ".tmpl")
+		dest := destName
+		if outDir != "" {
+			rel, err := filepath.Rel(dir, destName)
+			if err != nil {
+				return errors.Wrapf(err, "cannot compute relative path for '%s'", path)
+			}
+			dest = filepath.Join(outDir, rel)
+		}
+		if !opts.DryRun {
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return errors.Wrapf(err, "cannot create output directory for '%s'", dest)
+			}
+		}
+
+		changed, err := renderFile(path, data, missingKey, base, dest, opts)
+		if err != nil {
+			return err
+		}
+		if changed {
+			anyChanged = true
+		}
+		return nil
+	})
+	return anyChanged, err
+}
+
+// writeOutput delivers content to dest. In --dry-run mode it prints a
+// unified diff against the existing file (if any) and leaves disk state
+// untouched. Otherwise it writes via a temp file in the same directory
+// followed by os.Rename, so a crash mid-write never leaves a corrupted
+// dest, and preserves mode. With --write-if-changed, a dest whose content
+// already matches is left alone so its mtime is preserved.
+func writeOutput(dest string, content []byte, mode os.FileMode, opts outputOptions) (bool, error) {
+	existing, readErr := ioutil.ReadFile(dest)
+	exists := readErr == nil
+	changed := !exists || !bytes.Equal(existing, content)
+
+	if opts.DryRun {
+		if changed {
+			fmt.Fprint(os.Stdout, unifiedDiff(dest, string(existing), string(content)))
+		}
+		return changed, nil
+	}
+
+	if opts.WriteIfChanged && exists && !changed {
+		return false, nil
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), ".render-")
+	if err != nil {
+		return changed, errors.Wrapf(err, "cannot create temp file for '%s'", dest)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return changed, errors.Wrapf(err, "cannot write temp file for '%s'", dest)
+	}
+	if err := tmp.Close(); err != nil {
+		return changed, errors.Wrapf(err, "cannot close temp file for '%s'", dest)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return changed, errors.Wrapf(err, "cannot set mode on '%s'", dest)
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return changed, errors.Wrapf(err, "cannot move temp file into '%s'", dest)
+	}
+	return changed, nil
+}
+
+// unifiedDiff renders a minimal unified-style diff between before and
+// after, enough to show a human what --dry-run would change.
+func unifiedDiff(name, before, after string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", name, name)
+
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+
+	max := len(beforeLines)
+	if len(afterLines) > max {
+		max = len(afterLines)
+	}
+	for i := 0; i < max; i++ {
+		var b, a string
+		var hasB, hasA bool
+		if i < len(beforeLines) {
+			b, hasB = beforeLines[i], true
+		}
+		if i < len(afterLines) {
+			a, hasA = afterLines[i], true
+		}
+		if hasB && hasA && b == a {
+			continue
+		}
+		if hasB {
+			fmt.Fprintf(&buf, "-%s\n", b)
+		}
+		if hasA {
+			fmt.Fprintf(&buf, "+%s\n", a)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndirWithoutOutdirRendersInPlace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "render-indir-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.yaml.tmpl"), []byte("{{ .name }}"), 0644))
+
+	stdout, _, err := run("--var=name=render", "--indir", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "", stdout)
+
+	rendered, err := ioutil.ReadFile(filepath.Join(dir, "a.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "render", string(rendered))
+
+	_, err = os.Stat(filepath.Join(dir, "a.yaml.tmpl"))
+	assert.NoError(t, err, "source .tmpl file should be left untouched")
+}
+
+func TestIndirWithOutdirStripsTmplSuffix(t *testing.T) {
+	in, err := ioutil.TempDir("", "render-indir-in-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(in) }()
+	out, err := ioutil.TempDir("", "render-indir-out-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(out) }()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(in, "a.yaml.tmpl"), []byte("{{ .name }}"), 0644))
+
+	_, _, err = run("--var=name=render", "--indir", in, "--outdir", out)
+	assert.NoError(t, err)
+
+	rendered, err := ioutil.ReadFile(filepath.Join(out, "a.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "render", string(rendered))
+
+	_, err = os.Stat(filepath.Join(out, "a.yaml.tmpl"))
+	assert.True(t, os.IsNotExist(err), "output file should not retain the .tmpl suffix")
+}
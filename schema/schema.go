@@ -0,0 +1,100 @@
+// Package schema validates a rendering context against a JSON Schema, and
+// infers a starter schema from an existing config, for the `render schema`
+// subcommand.
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Validate checks data against the JSON Schema at schemaPath, returning an
+// error naming the first violation as a JSON pointer, e.g.
+// "/resourceQuota/hard/cpu: expected integer, got string".
+func Validate(data map[string]interface{}, schemaPath string) error {
+	schemaLoader := gojsonschema.NewReferenceLoader("file://" + schemaPath)
+	docLoader := gojsonschema.NewGoLoader(data)
+
+	result, err := gojsonschema.Validate(schemaLoader, docLoader)
+	if err != nil {
+		return errors.Wrapf(err, "cannot validate against schema '%s'", schemaPath)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	first := result.Errors()[0]
+	return errors.Errorf("%s: %s", fieldPointer(first), first.Description())
+}
+
+// fieldPointer returns the JSON pointer naming the field a validation error
+// applies to. For a RequiredError, Field() names the *parent* object (e.g.
+// "(root)" for a missing top-level property), not the missing property
+// itself, so the missing property's name (held in Details()["property"]) is
+// appended to point at the actual offending field.
+func fieldPointer(resultErr gojsonschema.ResultError) string {
+	field := resultErr.Field()
+
+	if _, ok := resultErr.(*gojsonschema.RequiredError); ok {
+		if property, ok := resultErr.Details()["property"].(string); ok {
+			if field == "(root)" {
+				return "/" + property
+			}
+			return "/" + strings.ReplaceAll(field, ".", "/") + "/" + property
+		}
+	}
+
+	return "/" + strings.ReplaceAll(field, ".", "/")
+}
+
+// Infer produces a starter JSON Schema describing data's shape, for `render
+// schema` to emit as a seed the user can then tighten by hand.
+func Infer(data map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": inferProperties(data),
+	}
+}
+
+func inferProperties(data map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	for key, value := range data {
+		props[key] = inferType(value)
+	}
+	return props
+}
+
+func inferType(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": inferProperties(v),
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return map[string]interface{}{
+				"type":  "array",
+				"items": inferType(v[0]),
+			}
+		}
+		return map[string]interface{}{"type": "array"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case int, float64:
+		return map[string]interface{}{"type": "number"}
+	case nil:
+		return map[string]interface{}{"type": "null"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// MarshalIndent pretty-prints an inferred or hand-built schema.
+func MarshalIndent(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSchema(t *testing.T, content string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "render-schema-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Remove(f.Name()) })
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte(content), 0644))
+	return f.Name()
+}
+
+func TestValidate_Valid(t *testing.T) {
+	path := writeSchema(t, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+
+	err := Validate(map[string]interface{}{"name": "render"}, path)
+	assert.NoError(t, err)
+}
+
+func TestValidate_ReportsJSONPointerAndDescription(t *testing.T) {
+	path := writeSchema(t, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+
+	err := Validate(map[string]interface{}{"name": 1}, path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/name")
+}
+
+func TestValidate_MissingRequiredProperty(t *testing.T) {
+	path := writeSchema(t, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+
+	err := Validate(map[string]interface{}{}, path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/name")
+}
+
+func TestValidate_MissingNestedRequiredProperty(t *testing.T) {
+	path := writeSchema(t, `{
+		"type": "object",
+		"properties": {
+			"resourceQuota": {
+				"type": "object",
+				"properties": {
+					"hard": {
+						"type": "object",
+						"properties": {"cpu": {"type": "string"}},
+						"required": ["cpu"]
+					}
+				}
+			}
+		}
+	}`)
+
+	data := map[string]interface{}{
+		"resourceQuota": map[string]interface{}{
+			"hard": map[string]interface{}{},
+		},
+	}
+	err := Validate(data, path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/resourceQuota/hard/cpu")
+}
+
+func TestInfer_BasicTypes(t *testing.T) {
+	data := map[string]interface{}{
+		"name":    "render",
+		"count":   3,
+		"enabled": true,
+		"tags":    []interface{}{"a", "b"},
+		"nested":  map[string]interface{}{"key": "value"},
+		"missing": nil,
+	}
+
+	result := Infer(data)
+	assert.Equal(t, "object", result["type"])
+
+	props, ok := result["properties"].(map[string]interface{})
+	require.True(t, ok)
+
+	assert.Equal(t, map[string]interface{}{"type": "string"}, props["name"])
+	assert.Equal(t, map[string]interface{}{"type": "number"}, props["count"])
+	assert.Equal(t, map[string]interface{}{"type": "boolean"}, props["enabled"])
+	assert.Equal(t, map[string]interface{}{"type": "null"}, props["missing"])
+
+	tags, ok := props["tags"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "array", tags["type"])
+
+	nested, ok := props["nested"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "object", nested["type"])
+}
+
+func TestMarshalIndent(t *testing.T) {
+	out, err := MarshalIndent(map[string]interface{}{"type": "object"})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "\"type\": \"object\"")
+}
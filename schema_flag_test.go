@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTempSchema(t *testing.T, content string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "render-schema-flag-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Remove(f.Name()) })
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte(content), 0644))
+	return f.Name()
+}
+
+func TestSchemaFlagRejectsInvalidContext(t *testing.T) {
+	path := writeTempSchema(t, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+
+	stdin := "{{ .name }}"
+	_, stderr, err := runStdin(&stdin, "--schema", path)
+
+	assert.EqualError(t, err, "exit status 1")
+	assert.Contains(t, stderr, "/name")
+}
+
+func TestSchemaFlagAllowsValidContext(t *testing.T) {
+	path := writeTempSchema(t, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+
+	stdin := "{{ .name }}"
+	stdout, _, err := runStdin(&stdin, "--var=name=render", "--schema", path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "render", stdout)
+}
+
+func TestSchemaSubcommandInfersFromVars(t *testing.T) {
+	stdout, _, err := run("schema", "--var=name=render", "--var=count:int=3")
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, `"name": {`)
+	assert.Contains(t, stdout, `"type": "string"`)
+}
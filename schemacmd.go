@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+
+	"github.com/VirtusLab/render/schema"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// schemaCommand implements `render schema`, which infers a starter JSON
+// Schema from the merged --config/--var context, lowering the adoption
+// cost of --schema validation on an existing template.
+var schemaCommand = cli.Command{
+	Name:  "schema",
+	Usage: "print a starter JSON Schema inferred from --config/--var",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "config",
+			Usage: "config file to infer the schema from, can be used multiple times",
+		},
+		cli.StringSliceFlag{
+			Name:  "var",
+			Usage: "a key=value pair (dot notation supported) to merge into the inferred context, can be used multiple times",
+		},
+		cli.StringFlag{
+			Name:  "config-sha256",
+			Usage: "expected sha256 checksum of a https:// --config, for supply-chain pinning",
+		},
+		cli.BoolFlag{
+			Name:  "refresh",
+			Usage: "bypass the cache and re-fetch remote --config sources",
+		},
+		cli.BoolFlag{
+			Name:  "offline",
+			Usage: "forbid network access, failing on any uncached remote --config source",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		data, err := buildContext(c)
+		if err != nil {
+			return err
+		}
+
+		out, err := schema.MarshalIndent(schema.Infer(data))
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(out, '\n'))
+		return err
+	},
+}
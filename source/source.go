@@ -0,0 +1,314 @@
+// Package source resolves --config/--in references that may be remote
+// URLs (https://, git::https://… and s3://) in addition to plain
+// filesystem paths, caching anything fetched under a local cache directory
+// keyed by the reference itself. A cached https:// or s3:// artifact is
+// revalidated against the origin's ETag on every resolve (rather than
+// trusted indefinitely), so a change upstream is picked up without
+// requiring --refresh.
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Options controls how a remote reference is fetched and cached.
+type Options struct {
+	// Refresh bypasses the cache and re-fetches even if an entry exists.
+	Refresh bool
+	// Offline forbids any network access; an uncached reference is an error.
+	Offline bool
+	// SHA256, when set, pins the expected checksum of an https:// fetch.
+	SHA256 string
+}
+
+// CacheDir returns $XDG_CACHE_HOME/render, falling back to the OS default
+// user cache directory when XDG_CACHE_HOME is unset.
+func CacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "render"), nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot determine cache directory")
+	}
+	return filepath.Join(base, "render"), nil
+}
+
+// Resolve returns a local filesystem path for ref. A plain path is
+// returned unchanged; a https://, git:: or s3:// reference is fetched (if
+// not already cached, or if opts.Refresh is set) and the path to the
+// cached copy is returned instead.
+func Resolve(ref string, opts Options) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "git::"):
+		return resolveGit(strings.TrimPrefix(ref, "git::"), opts)
+	case strings.HasPrefix(ref, "s3://"):
+		return resolveS3(ref, opts)
+	case strings.HasPrefix(ref, "http://"), strings.HasPrefix(ref, "https://"):
+		return resolveHTTP(ref, opts)
+	default:
+		return ref, nil
+	}
+}
+
+// IsRemote reports whether ref is a git::, s3:// or http(s):// reference
+// rather than a plain filesystem path.
+func IsRemote(ref string) bool {
+	return strings.HasPrefix(ref, "git::") ||
+		strings.HasPrefix(ref, "s3://") ||
+		strings.HasPrefix(ref, "http://") ||
+		strings.HasPrefix(ref, "https://")
+}
+
+func cacheKey(ref string) string {
+	sum := sha256.Sum256([]byte(ref))
+	return hex.EncodeToString(sum[:])
+}
+
+// etagPath returns the sidecar file resolveHTTP stores a cached artifact's
+// ETag in, alongside the artifact itself, so a later resolve can revalidate
+// against the origin instead of trusting a stale cache indefinitely.
+func etagPath(dest string) string {
+	return dest + ".etag"
+}
+
+func resolveHTTP(ref string, opts Options) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(dir, cacheKey(ref))
+
+	_, statErr := os.Stat(dest)
+	haveCache := statErr == nil && !opts.Refresh
+
+	if opts.Offline {
+		if haveCache {
+			return dest, verifyChecksum(dest, opts.SHA256)
+		}
+		return "", errors.Errorf("'%s' is not cached and --offline forbids network access", ref)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, ref, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot build request for '%s'", ref)
+	}
+	if haveCache {
+		if etag, err := ioutil.ReadFile(etagPath(dest)); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if haveCache {
+			return dest, verifyChecksum(dest, opts.SHA256)
+		}
+		return "", errors.Wrapf(err, "cannot fetch '%s'", ref)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if haveCache && resp.StatusCode == http.StatusNotModified {
+		return dest, verifyChecksum(dest, opts.SHA256)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("cannot fetch '%s': unexpected status %s", ref, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read response body for '%s'", ref)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "cannot create cache directory '%s'", dir)
+	}
+	if err := ioutil.WriteFile(dest, body, 0644); err != nil {
+		return "", errors.Wrapf(err, "cannot write cache file '%s'", dest)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		if err := ioutil.WriteFile(etagPath(dest), []byte(etag), 0644); err != nil {
+			return "", errors.Wrapf(err, "cannot write etag cache file for '%s'", dest)
+		}
+	} else {
+		_ = os.Remove(etagPath(dest))
+	}
+
+	return dest, verifyChecksum(dest, opts.SHA256)
+}
+
+func verifyChecksum(path, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	got := hex.EncodeToString(sum[:])
+	if got != expected {
+		return errors.Errorf("checksum mismatch for '%s': expected %s, got %s", path, expected, got)
+	}
+	return nil
+}
+
+// resolveGit fetches ref in go-getter's "host/repo//path?ref=v1.2.3" style:
+// a repository URL, an optional "//subpath" and an optional "?ref=" query
+// parameter selecting a branch, tag or commit.
+func resolveGit(ref string, opts Options) (string, error) {
+	repoURL, subPath, gitRef, err := parseGitRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	repoDir := filepath.Join(dir, "git", cacheKey(repoURL+"@"+cacheRefKey(gitRef)))
+
+	if opts.Refresh {
+		_ = os.RemoveAll(repoDir)
+	}
+
+	if _, err := os.Stat(repoDir); os.IsNotExist(err) {
+		if opts.Offline {
+			return "", errors.Errorf("'%s' is not cached and --offline forbids network access", ref)
+		}
+		if err := os.MkdirAll(filepath.Dir(repoDir), 0755); err != nil {
+			return "", errors.Wrapf(err, "cannot create cache directory for '%s'", ref)
+		}
+
+		// A shallow clone only has the tip commit of the default branch, so
+		// "--depth 1" can only be used when no ref was requested; checking
+		// out a tag, branch or commit that isn't that exact tip afterwards
+		// would fail against a shallow clone. When a ref was requested,
+		// clone in full instead and check it out, which handles branches,
+		// tags and commit SHAs alike.
+		cloneArgs := []string{"clone"}
+		if gitRef == "" {
+			cloneArgs = append(cloneArgs, "--depth", "1")
+		}
+		cloneArgs = append(cloneArgs, repoURL, repoDir)
+		if out, err := exec.Command("git", cloneArgs...).CombinedOutput(); err != nil {
+			return "", errors.Wrapf(err, "cannot clone '%s': %s", repoURL, out)
+		}
+		if gitRef != "" {
+			if out, err := exec.Command("git", "-C", repoDir, "checkout", gitRef).CombinedOutput(); err != nil {
+				return "", errors.Wrapf(err, "cannot checkout '%s' in '%s': %s", gitRef, repoURL, out)
+			}
+		}
+	}
+
+	return filepath.Join(repoDir, subPath), nil
+}
+
+// cacheRefKey returns the ref used to key the cache directory, so an
+// unspecified ref (the default branch) still gets a stable, non-empty key.
+func cacheRefKey(gitRef string) string {
+	if gitRef == "" {
+		return "HEAD"
+	}
+	return gitRef
+}
+
+func parseGitRef(ref string) (repoURL, subPath, gitRef string, err error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", "", "", errors.Wrapf(err, "cannot parse git source '%s'", ref)
+	}
+
+	gitRef = u.Query().Get("ref")
+	u.RawQuery = ""
+
+	path := u.Path
+	if idx := strings.Index(path, "//"); idx >= 0 {
+		subPath = strings.TrimPrefix(path[idx+1:], "/")
+		u.Path = path[:idx]
+	}
+	return u.String(), subPath, gitRef, nil
+}
+
+// resolveS3 fetches an s3://bucket/key reference via the aws CLI, which is
+// assumed to already be configured with credentials, mirroring how the
+// rest of render shells out to existing tools rather than vendoring a
+// provider-specific SDK.
+func resolveS3(ref string, opts Options) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(dir, cacheKey(ref))
+
+	_, statErr := os.Stat(dest)
+	haveCache := statErr == nil && !opts.Refresh
+
+	if opts.Offline {
+		if haveCache {
+			return dest, nil
+		}
+		return "", errors.Errorf("'%s' is not cached and --offline forbids network access", ref)
+	}
+
+	if haveCache {
+		if cachedEtag, err := ioutil.ReadFile(etagPath(dest)); err == nil {
+			if remoteEtag, err := s3ETag(ref); err == nil && remoteEtag == string(cachedEtag) {
+				return dest, nil
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "cannot create cache directory '%s'", dir)
+	}
+
+	cmd := exec.Command("aws", "s3", "cp", ref, dest)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", errors.Wrapf(err, "cannot fetch '%s': %s", ref, out)
+	}
+
+	if etag, err := s3ETag(ref); err == nil && etag != "" {
+		if err := ioutil.WriteFile(etagPath(dest), []byte(etag), 0644); err != nil {
+			return "", errors.Wrapf(err, "cannot write etag cache file for '%s'", dest)
+		}
+	} else {
+		_ = os.Remove(etagPath(dest))
+	}
+
+	return dest, nil
+}
+
+// s3ETag returns the current ETag of an s3://bucket/key object via the aws
+// CLI's head-object, so a cached copy can be revalidated against the origin
+// without re-downloading it.
+func s3ETag(ref string) (string, error) {
+	bucket, key, err := parseS3Ref(ref)
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command("aws", "s3api", "head-object",
+		"--bucket", bucket, "--key", key, "--query", "ETag", "--output", "text").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(string(out)), `"`), nil
+}
+
+func parseS3Ref(ref string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(ref, "s3://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid s3 reference '%s', expected s3://bucket/key", ref)
+	}
+	return parts[0], parts[1], nil
+}
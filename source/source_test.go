@@ -0,0 +1,242 @@
+package source
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withCacheDir points XDG_CACHE_HOME at a fresh temp directory for the
+// duration of a test, so fetches never touch the real user cache.
+func withCacheDir(t *testing.T) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "render-cache-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	old, hadOld := os.LookupEnv("XDG_CACHE_HOME")
+	require.NoError(t, os.Setenv("XDG_CACHE_HOME", dir))
+	t.Cleanup(func() {
+		if hadOld {
+			_ = os.Setenv("XDG_CACHE_HOME", old)
+		} else {
+			_ = os.Unsetenv("XDG_CACHE_HOME")
+		}
+	})
+}
+
+func TestResolve_PlainPath(t *testing.T) {
+	path, err := Resolve("examples/example.config.yaml", Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "examples/example.config.yaml", path)
+}
+
+func TestResolveHTTP_CachesAndVerifiesChecksum(t *testing.T) {
+	withCacheDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("name: render\n"))
+	}))
+	defer server.Close()
+
+	path, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name: render\n", string(content))
+
+	// A second, non-refresh resolve must hit the cache, not the server.
+	server.Close()
+	path2, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, path, path2)
+}
+
+func TestResolveHTTP_RevalidatesAgainstETag(t *testing.T) {
+	withCacheDir(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("name: render\n"))
+	}))
+	defer server.Close()
+
+	path, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// A second, non-refresh resolve revalidates against the origin's ETag
+	// (a conditional GET, answered with 304) instead of re-downloading.
+	path2, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, path, path2)
+
+	content, err := ioutil.ReadFile(path2)
+	require.NoError(t, err)
+	assert.Equal(t, "name: render\n", string(content))
+}
+
+func TestResolveHTTP_ETagChangeRefetches(t *testing.T) {
+	withCacheDir(t)
+
+	etag := `"v1"`
+	body := "name: render\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	_, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+
+	etag = `"v2"`
+	body = "name: render-v2\n"
+	path, err := Resolve(server.URL, Options{})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name: render-v2\n", string(content))
+}
+
+func TestResolveHTTP_ChecksumMismatch(t *testing.T) {
+	withCacheDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("name: render\n"))
+	}))
+	defer server.Close()
+
+	_, err := Resolve(server.URL, Options{SHA256: "deadbeef"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestResolveHTTP_OfflineUncached(t *testing.T) {
+	withCacheDir(t)
+
+	_, err := Resolve("https://example.invalid/config.yaml", Options{Offline: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--offline")
+}
+
+// fileURL turns a local directory into a file:// URL. git treats a bare
+// directory argument as a "local" clone and silently ignores --depth for
+// it (printing "--depth is ignored in local clones; use file:// instead.");
+// only a file:// URL actually exercises --depth the way a real remote
+// would, so every git test below clones through one.
+func fileURL(path string) string {
+	return "file://" + path
+}
+
+// initGitRepo creates a throwaway local git repository with two commits on
+// its default branch and a tag on the first, so tests can exercise both
+// "no ref" (clone default branch) and "ref=tag" (checkout after clone).
+func initGitRepo(t *testing.T) (dir, tag string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "render-git-origin-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=render-test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=render-test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v: %s", args, out)
+	}
+
+	run("init")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "input.tmpl"), []byte("v1"), 0644))
+	run("add", "input.tmpl")
+	run("commit", "-m", "v1")
+	run("tag", "v1")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "input.tmpl"), []byte("v2"), 0644))
+	run("add", "input.tmpl")
+	run("commit", "-m", "v2")
+
+	return dir, "v1"
+}
+
+func TestResolveGit_DefaultBranchHasNoInvalidBranchFlag(t *testing.T) {
+	withCacheDir(t)
+	origin, _ := initGitRepo(t)
+
+	path, err := Resolve("git::"+fileURL(origin), Options{})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(path, "input.tmpl"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+}
+
+// TestResolveGit_RefChecksOutTag pins to the first commit's tag, not the
+// tip of the default branch. Over a file:// URL, --depth is honored the
+// same as it would be against a real remote, so a shallow clone that then
+// tries to check out a non-tip ref fails exactly as it would in
+// production; this is the regression test for that bug.
+func TestResolveGit_RefChecksOutTag(t *testing.T) {
+	withCacheDir(t)
+	origin, tag := initGitRepo(t)
+
+	path, err := Resolve("git::"+fileURL(origin)+"?ref="+tag, Options{})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(path, "input.tmpl"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+}
+
+func TestResolveGit_SubPath(t *testing.T) {
+	withCacheDir(t)
+	origin, err := ioutil.TempDir("", "render-git-subpath-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(origin) }()
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=render-test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=render-test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v: %s", args, out)
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(origin, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(origin, "sub", "input.tmpl"), []byte("nested"), 0644))
+	run("init")
+	run("add", "sub/input.tmpl")
+	run("commit", "-m", "init")
+
+	path, err := Resolve("git::"+fileURL(origin)+"//sub", Options{})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(path, "input.tmpl"))
+	require.NoError(t, err)
+	assert.Equal(t, "nested", string(content))
+}
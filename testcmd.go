@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+
+	"github.com/VirtusLab/render/testrunner"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// testCommand implements `render test`, a discovery-based harness for
+// template test cases: directories containing input.tmpl, vars.yaml and
+// expected.out, modeled on the hand-rolled tests in main_test.go.
+var testCommand = cli.Command{
+	Name:  "test",
+	Usage: "discover and run template test cases below a directory",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dir",
+			Value: ".",
+			Usage: "root directory to discover test cases under",
+		},
+		cli.BoolFlag{
+			Name:  "update",
+			Usage: "rewrite expected.out for every case instead of failing on mismatch",
+		},
+		cli.IntFlag{
+			Name:  "jobs",
+			Value: 1,
+			Usage: "number of test cases to run in parallel",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: "report format: text, json or junit",
+		},
+		cli.StringFlag{
+			Name:  "report",
+			Usage: "write the machine-readable report to this file instead of stdout",
+		},
+		cli.BoolFlag{
+			Name:  "unsafe-ignore-missing-keys",
+			Usage: "do not fail when a template references a key missing from the context",
+		},
+	},
+	Action: testAction,
+}
+
+func testAction(c *cli.Context) error {
+	cases, err := testrunner.Discover(c.String("dir"))
+	if err != nil {
+		return err
+	}
+
+	unsafe := c.Bool("unsafe-ignore-missing-keys")
+	render := func(templateContent, varsContent []byte) (string, string, error) {
+		return renderWithContext(templateContent, varsContent, unsafe)
+	}
+
+	results := testrunner.Run(cases, render, c.Int("jobs"), c.Bool("update"))
+
+	if err := writeReport(c, results); err != nil {
+		return err
+	}
+
+	if code := testrunner.ExitCode(results); code != 0 {
+		return cli.NewExitError("render test: one or more cases failed", code)
+	}
+	return nil
+}
+
+func writeReport(c *cli.Context, results []testrunner.Result) error {
+	out := os.Stdout
+	if path := c.String("report"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return errors.Wrapf(err, "cannot create report file '%s'", path)
+		}
+		defer func() { _ = f.Close() }()
+
+		switch c.String("format") {
+		case "json":
+			return testrunner.WriteJSON(f, results)
+		case "junit":
+			return testrunner.WriteJUnit(f, results)
+		default:
+			testrunner.WriteSummary(f, results)
+			return nil
+		}
+	}
+
+	switch c.String("format") {
+	case "json":
+		return testrunner.WriteJSON(out, results)
+	case "junit":
+		return testrunner.WriteJUnit(out, results)
+	default:
+		testrunner.WriteSummary(out, results)
+		return nil
+	}
+}
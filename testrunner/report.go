@@ -0,0 +1,90 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// jsonResult is the JSON-serializable shape of a single Result.
+type jsonResult struct {
+	Name       string  `json:"name"`
+	Passed     bool    `json:"passed"`
+	Reason     string  `json:"reason,omitempty"`
+	DurationMS float64 `json:"durationMs"`
+}
+
+// WriteJSON writes results as a JSON array to w, for machine consumption in CI.
+func WriteJSON(w io.Writer, results []Result) error {
+	out := make([]jsonResult, 0, len(results))
+	for _, r := range results {
+		out = append(out, jsonResult{
+			Name:       r.Case.Name,
+			Passed:     r.Passed,
+			Reason:     r.Reason,
+			DurationMS: r.Duration.Seconds() * 1000,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// junitSuite and junitCase mirror the subset of the JUnit XML schema that CI
+// systems (Jenkins, GitLab, GitHub Actions) actually read.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name    string   `xml:"name,attr"`
+	Time    float64  `xml:"time,attr"`
+	Failure *failure `xml:"failure,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as a JUnit testsuite XML document to w.
+func WriteJUnit(w io.Writer, results []Result) error {
+	suite := junitSuite{Name: "render test"}
+	for _, r := range results {
+		tc := junitCase{Name: r.Case.Name, Time: r.Duration.Seconds()}
+		if !r.Passed {
+			suite.Failures++
+			tc.Failure = &failure{Message: "mismatch", Text: r.Reason}
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := fmt.Fprint(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(suite)
+}
+
+// WriteSummary writes a short human-readable summary to w.
+func WriteSummary(w io.Writer, results []Result) {
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s  %s  (%s)\n", status, r.Case.Name, r.Duration)
+		if !r.Passed && r.Reason != "" {
+			fmt.Fprintf(w, "%s\n", r.Reason)
+		}
+	}
+	fmt.Fprintf(w, "\n%s cases passed\n", FormatCaseCount(results))
+}
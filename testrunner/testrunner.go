@@ -0,0 +1,266 @@
+// Package testrunner implements the discovery-based template test harness
+// used by the `render test` subcommand. A test case is a directory
+// containing an input.tmpl, a vars.yaml and an expected.out, with optional
+// expected_stderr, expected_exit and timeout files for finer control.
+package testrunner
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultCaseTimeout bounds how long a single case's render may run for,
+// mirroring the killIn constant used to bound the CLI's own tests.
+const defaultCaseTimeout = 10 * time.Second
+
+const (
+	inputFile          = "input.tmpl"
+	varsFile           = "vars.yaml"
+	expectedFile       = "expected.out"
+	expectedStderrFile = "expected_stderr"
+	expectedExitFile   = "expected_exit"
+	timeoutFile        = "timeout"
+)
+
+// Render renders a template against a set of variables. The CLI supplies
+// its own renderer so the harness exercises the exact same code path as a
+// normal `render` invocation.
+type Render func(templateContent, varsContent []byte) (stdout, stderr string, err error)
+
+// Case is a single discovered template test case.
+type Case struct {
+	Name    string
+	Dir     string
+	Timeout time.Duration
+}
+
+// Result is the outcome of running a single Case.
+type Result struct {
+	Case     Case
+	Passed   bool
+	Reason   string
+	Got      string
+	Expected string
+	Duration time.Duration
+}
+
+// Discover walks root looking for directories that contain input.tmpl,
+// vars.yaml and expected.out, and returns one Case per match, sorted by
+// directory name for reproducible output.
+func Discover(root string) ([]Case, error) {
+	var cases []Case
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if !isCaseDir(path) {
+			return nil
+		}
+
+		timeout := defaultCaseTimeout
+		if raw, err := ioutil.ReadFile(filepath.Join(path, timeoutFile)); err == nil {
+			if parsed, err := time.ParseDuration(strings.TrimSpace(string(raw))); err == nil {
+				timeout = parsed
+			}
+		}
+
+		name, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			name = path
+		}
+		cases = append(cases, Case{Name: name, Dir: path, Timeout: timeout})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot discover test cases under '%s'", root)
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+func isCaseDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, inputFile))
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, expectedFile))
+	return err == nil
+}
+
+// Run executes every case, up to jobs at a time, using render to produce
+// actual output. When update is true, mismatches rewrite expected.out in
+// place instead of failing, supporting the golden-file workflow.
+func Run(cases []Case, render Render, jobs int, update bool) []Result {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]Result, len(cases))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+
+	for i, tc := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, tc Case) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runCase(tc, render, update)
+		}(i, tc)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func runCase(tc Case, render Render, update bool) Result {
+	start := time.Now()
+	result := Result{Case: tc}
+
+	tmplContent, err := ioutil.ReadFile(filepath.Join(tc.Dir, inputFile))
+	if err != nil {
+		result.Reason = errors.Wrap(err, "cannot read input.tmpl").Error()
+		return result
+	}
+
+	varsContent, err := ioutil.ReadFile(filepath.Join(tc.Dir, varsFile))
+	if err != nil && !os.IsNotExist(err) {
+		result.Reason = errors.Wrap(err, "cannot read vars.yaml").Error()
+		return result
+	}
+
+	expected, err := ioutil.ReadFile(filepath.Join(tc.Dir, expectedFile))
+	if err != nil {
+		result.Reason = errors.Wrap(err, "cannot read expected.out").Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tc.Timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	var stdout, stderr string
+	var renderErr error
+	go func() {
+		stdout, stderr, renderErr = render(tmplContent, varsContent)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		result.Reason = errors.Errorf("case timed out after %s", tc.Timeout).Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	result.Got = stdout
+	result.Expected = string(expected)
+	result.Duration = time.Since(start)
+
+	if update {
+		if stdout != result.Expected {
+			if err := ioutil.WriteFile(filepath.Join(tc.Dir, expectedFile), []byte(stdout), 0644); err != nil {
+				result.Reason = errors.Wrap(err, "cannot update expected.out").Error()
+				return result
+			}
+		}
+		result.Passed = true
+		return result
+	}
+
+	if renderErr != nil {
+		if !expectationMatches(tc.Dir, expectedExitFile, "1") {
+			result.Reason = renderErr.Error()
+			return result
+		}
+	}
+
+	if raw, err := ioutil.ReadFile(filepath.Join(tc.Dir, expectedStderrFile)); err == nil {
+		if strings.TrimSpace(stderr) != strings.TrimSpace(string(raw)) {
+			result.Reason = "stderr did not match expected_stderr"
+			return result
+		}
+	}
+
+	if stdout != result.Expected {
+		result.Reason = diff(result.Expected, stdout)
+		return result
+	}
+
+	result.Passed = true
+	return result
+}
+
+func expectationMatches(dir, file, want string) bool {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(raw)) == want
+}
+
+// diff produces a minimal line-based unified-ish diff between expected and
+// got, good enough for a human reading a test failure.
+func diff(expected, got string) string {
+	var buf bytes.Buffer
+	expLines := strings.Split(expected, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	max := len(expLines)
+	if len(gotLines) > max {
+		max = len(gotLines)
+	}
+	for i := 0; i < max; i++ {
+		var exp, gl string
+		if i < len(expLines) {
+			exp = expLines[i]
+		}
+		if i < len(gotLines) {
+			gl = gotLines[i]
+		}
+		if exp == gl {
+			continue
+		}
+		buf.WriteString("- " + exp + "\n")
+		buf.WriteString("+ " + gl + "\n")
+	}
+	return buf.String()
+}
+
+// ExitCode returns the overall process exit code for a set of results: 0
+// when every case passed, 1 otherwise.
+func ExitCode(results []Result) int {
+	for _, r := range results {
+		if !r.Passed {
+			return 1
+		}
+	}
+	return 0
+}
+
+// FormatCaseCount renders a short "passed/total" string for a CLI summary.
+func FormatCaseCount(results []Result) string {
+	passed := 0
+	for _, r := range results {
+		if r.Passed {
+			passed++
+		}
+	}
+	return strconv.Itoa(passed) + "/" + strconv.Itoa(len(results))
+}
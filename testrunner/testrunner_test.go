@@ -0,0 +1,145 @@
+package testrunner
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCase(t *testing.T, root, name string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for file, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+}
+
+func echoRender(stdout string, err error) Render {
+	return func(templateContent, varsContent []byte) (string, string, error) {
+		return stdout, "", err
+	}
+}
+
+func TestDiscover_FindsOnlyCompleteCases(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-discover-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "complete", map[string]string{
+		inputFile:    "{{ .x }}",
+		expectedFile: "value",
+	})
+	writeCase(t, root, "incomplete", map[string]string{
+		inputFile: "{{ .x }}",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+	require.Len(t, cases, 1)
+	assert.Equal(t, "complete", cases[0].Name)
+	assert.Equal(t, defaultCaseTimeout, cases[0].Timeout)
+}
+
+func TestDiscover_HonoursTimeoutFile(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-discover-timeout-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "slow", map[string]string{
+		inputFile:    "{{ .x }}",
+		expectedFile: "value",
+		timeoutFile:  "50ms",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+	require.Len(t, cases, 1)
+	assert.Equal(t, "50ms", cases[0].Timeout.String())
+}
+
+func TestRun_Pass(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-run-pass-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "ok", map[string]string{
+		inputFile:    "{{ .x }}",
+		expectedFile: "value",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+
+	results := Run(cases, echoRender("value", nil), 1, false)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, 0, ExitCode(results))
+	assert.Equal(t, "1/1", FormatCaseCount(results))
+}
+
+func TestRun_Mismatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-run-mismatch-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "bad", map[string]string{
+		inputFile:    "{{ .x }}",
+		expectedFile: "value",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+
+	results := Run(cases, echoRender("other", nil), 1, false)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Equal(t, 1, ExitCode(results))
+	assert.Equal(t, "0/1", FormatCaseCount(results))
+}
+
+func TestRun_Update(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-run-update-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "stale", map[string]string{
+		inputFile:    "{{ .x }}",
+		expectedFile: "old",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+
+	results := Run(cases, echoRender("new", nil), 1, true)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed)
+
+	updated, err := ioutil.ReadFile(filepath.Join(root, "stale", expectedFile))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(updated))
+}
+
+func TestRun_ExpectedExitAllowsRenderError(t *testing.T) {
+	root, err := ioutil.TempDir("", "testrunner-run-exit-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeCase(t, root, "fails", map[string]string{
+		inputFile:        "{{ .x }}",
+		expectedFile:     "",
+		expectedExitFile: "1",
+	})
+
+	cases, err := Discover(root)
+	require.NoError(t, err)
+
+	results := Run(cases, echoRender("", errors.New("boom")), 1, false)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed)
+}
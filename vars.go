@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseVar splits a "--var" argument of the form "a.b.c[:type]=value" into
+// the dotted path ["a", "b", "c"] and the value converted according to
+// type, which defaults to "string" when omitted. Supported types are
+// string, int, float, bool and json.
+func parseVar(raw string) (path []string, value interface{}, err error) {
+	idx := strings.Index(raw, "=")
+	if idx <= 0 {
+		return nil, nil, errors.Errorf("invalid --var '%s', expected key=value", raw)
+	}
+
+	key := raw[:idx]
+	varType := "string"
+	if ci := strings.LastIndex(key, ":"); ci >= 0 {
+		varType = key[ci+1:]
+		key = key[:ci]
+	}
+
+	typed, err := convertVar(varType, unquote(raw[idx+1:]))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid --var '%s'", raw)
+	}
+
+	return strings.Split(key, "."), typed, nil
+}
+
+// convertVar converts raw to the Go value that the given --var type
+// annotation requests.
+func convertVar(varType, raw string) (interface{}, error) {
+	switch varType {
+	case "string":
+		return raw, nil
+	case "int":
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expected an int")
+		}
+		return v, nil
+	case "float":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expected a float")
+		}
+		return v, nil
+	case "bool":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expected a bool")
+		}
+		return v, nil
+	case "json":
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, errors.Wrapf(err, "expected valid json")
+		}
+		return v, nil
+	default:
+		return nil, errors.Errorf("unknown type '%s'", varType)
+	}
+}
+
+// unquote strips a single matching pair of surrounding single or double
+// quotes, allowing values like --var greeting="hello world" on the shell.
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' && last == '"') || (first == '\'' && last == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// setNested writes value into data at the given dotted path, creating
+// intermediate maps as needed.
+func setNested(data map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		data[path[0]] = value
+		return
+	}
+
+	nested, ok := data[path[0]].(map[string]interface{})
+	if !ok {
+		nested = map[string]interface{}{}
+		data[path[0]] = nested
+	}
+	setNested(nested, path[1:], value)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVar_String(t *testing.T) {
+	path, value, err := parseVar("name=render")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name"}, path)
+	assert.Equal(t, "render", value)
+}
+
+func TestParseVar_Nested(t *testing.T) {
+	path, value, err := parseVar("a.b.c=leaf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, path)
+	assert.Equal(t, "leaf", value)
+}
+
+func TestParseVar_QuotedStringIsUnquoted(t *testing.T) {
+	_, value, err := parseVar(`name="with value"`)
+	require.NoError(t, err)
+	assert.Equal(t, "with value", value)
+}
+
+func TestParseVar_TypedInt(t *testing.T) {
+	_, value, err := parseVar("count:int=3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestParseVar_TypedFloat(t *testing.T) {
+	_, value, err := parseVar("ratio:float=1.5")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+}
+
+func TestParseVar_TypedBool(t *testing.T) {
+	_, value, err := parseVar("enabled:bool=true")
+	require.NoError(t, err)
+	assert.Equal(t, true, value)
+}
+
+func TestParseVar_TypedJSON(t *testing.T) {
+	_, value, err := parseVar(`tags:json=["a","b"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, value)
+}
+
+func TestParseVar_InvalidIntIsAnError(t *testing.T) {
+	_, _, err := parseVar("count:int=nope")
+	assert.Error(t, err)
+}
+
+func TestParseVar_UnknownTypeIsAnError(t *testing.T) {
+	_, _, err := parseVar("count:uuid=nope")
+	assert.Error(t, err)
+}
+
+func TestParseVar_MissingEqualsIsAnError(t *testing.T) {
+	_, _, err := parseVar("name")
+	assert.Error(t, err)
+}
+
+func TestSetNested_Flat(t *testing.T) {
+	data := map[string]interface{}{}
+	setNested(data, []string{"name"}, "render")
+	assert.Equal(t, map[string]interface{}{"name": "render"}, data)
+}
+
+func TestSetNested_CreatesIntermediateMaps(t *testing.T) {
+	data := map[string]interface{}{}
+	setNested(data, []string{"a", "b", "c"}, "leaf")
+
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": "leaf",
+			},
+		},
+	}, data)
+}
+
+func TestSetNested_PreservesSiblingKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"a": map[string]interface{}{"existing": "value"},
+	}
+	setNested(data, []string{"a", "new"}, "added")
+
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{
+			"existing": "value",
+			"new":      "added",
+		},
+	}, data)
+}
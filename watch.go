@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/VirtusLab/render/source"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// defaultDebounce is how long watch waits after the last filesystem event
+// before triggering a re-render, so a burst of saves from an editor only
+// triggers one render.
+const defaultDebounce = 200 * time.Millisecond
+
+// watch renders once, then keeps re-rendering whenever --in/--indir or any
+// --config file changes, until the process is interrupted. A render or
+// --on-change failure is logged as a structured event and watching
+// continues; it never terminates the process.
+func watch(c *cli.Context) error {
+	if _, err := renderOnce(c); err != nil {
+		logrus.WithError(err).Error("initial render failed")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "cannot start filesystem watcher")
+	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, path := range watchedPaths(c) {
+		if err := addRecursive(watcher, path); err != nil {
+			return errors.Wrapf(err, "cannot watch '%s'", path)
+		}
+	}
+
+	debounce := c.Duration("debounce")
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			logrus.WithField("file", event.Name).WithField("op", event.Op.String()).Debug("watch: change detected")
+
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() { onWatchTrigger(c) })
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.WithError(err).Error("watch: filesystem watcher error")
+		}
+	}
+}
+
+// onWatchTrigger re-renders and, on success, runs --on-change. Errors from
+// either step are logged, never propagated, so a bad save never kills the
+// watch loop.
+func onWatchTrigger(c *cli.Context) {
+	if _, err := renderOnce(c); err != nil {
+		logrus.WithError(err).Error("watch: render failed")
+		return
+	}
+	logrus.Info("watch: re-rendered")
+
+	onChange := c.String("on-change")
+	if onChange == "" {
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", onChange)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		logrus.WithError(err).WithField("output", string(output)).Error("watch: --on-change command failed")
+	}
+}
+
+// watchedPaths returns every local path watch should monitor: the input
+// file or directory, plus every --config file. A git::/s3:///http(s)://
+// --in or --config reference is not filesystem-watchable (the repo or
+// bucket it names, not its local cache, is the thing that changes), so it
+// is skipped rather than passed to addRecursive, which only understands
+// local paths.
+func watchedPaths(c *cli.Context) []string {
+	var paths []string
+	add := func(ref string) {
+		if ref == "" {
+			return
+		}
+		if source.IsRemote(ref) {
+			logrus.WithField("source", ref).Debug("watch: skipping remote source, it is not filesystem-watchable")
+			return
+		}
+		paths = append(paths, ref)
+	}
+
+	add(c.String("in"))
+	add(c.String("indir"))
+	for _, ref := range c.StringSlice("config") {
+		add(ref)
+	}
+	return paths
+}
+
+// addRecursive adds path to watcher; if path is a directory it also adds
+// every subdirectory, since fsnotify does not watch recursively on its own.
+func addRecursive(watcher *fsnotify.Watcher, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return watcher.Add(path)
+	}
+
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(p)
+		}
+		return nil
+	})
+}